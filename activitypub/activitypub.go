@@ -0,0 +1,125 @@
+// Package activitypub lets a reading list be followed from the fediverse
+// (Mastodon, GoToSocial, ...). It exposes the actor/webfinger/outbox/inbox
+// endpoints a follower's server needs and fans new readings out to
+// followers as Announce activities.
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"fmt"
+	"log"
+)
+
+// Service holds everything the ActivityPub handlers need: the shared DB
+// connection, the public base URL readers use to reach us, and the key
+// pair used to sign outbound deliveries. Every actor, inbox, outbox and
+// follower is scoped to a local user_id - there is no shared, all-users
+// actor.
+type Service struct {
+	db         *sql.DB
+	baseURL    string
+	privateKey *rsa.PrivateKey
+	deliveries chan delivery
+}
+
+// NewService creates the ActivityPub service, ensures its tables exist, and
+// starts the background delivery worker. baseURL is the externally visible
+// origin (e.g. "https://reading.example.com") used to build actor IDs.
+func NewService(db *sql.DB, baseURL string) (*Service, error) {
+	key, err := loadOrCreateKey(db)
+	if err != nil {
+		return nil, fmt.Errorf("activitypub: loading signing key: %w", err)
+	}
+
+	s := &Service{
+		db:         db,
+		baseURL:    baseURL,
+		privateKey: key,
+		deliveries: make(chan delivery, 64),
+	}
+
+	if err := s.ensureSchema(); err != nil {
+		return nil, fmt.Errorf("activitypub: ensuring schema: %w", err)
+	}
+
+	s.startWorker()
+	return s, nil
+}
+
+func (s *Service) ensureSchema() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS ap_followers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		actor_id TEXT NOT NULL,
+		inbox_url TEXT NOT NULL,
+		shared_inbox_url TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, actor_id)
+	)`)
+	return err
+}
+
+// userBase is the route prefix main.go mounts a user's actor/inbox/outbox
+// handlers under: /users/{userID}/{actor,inbox,outbox}.
+func (s *Service) userBase(userID int64) string {
+	return fmt.Sprintf("%s/users/%d", s.baseURL, userID)
+}
+
+// actorID builds the per-user actor IRI a follower's server resolves to
+// find our inbox/outbox/public key.
+func (s *Service) actorID(userID int64) string {
+	return s.userBase(userID) + "/actor"
+}
+
+func (s *Service) usernameByID(userID int64) (string, error) {
+	var username string
+	err := s.db.QueryRow("SELECT username FROM users WHERE id = ?", userID).Scan(&username)
+	return username, err
+}
+
+func (s *Service) userIDByUsername(username string) (int64, error) {
+	var userID int64
+	err := s.db.QueryRow("SELECT id FROM users WHERE username = ?", username).Scan(&userID)
+	return userID, err
+}
+
+func loadOrCreateKey(db *sql.DB) (*rsa.PrivateKey, error) {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS ap_keys (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		private_key_pem TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var pemStr string
+	err = db.QueryRow("SELECT private_key_pem FROM ap_keys WHERE id = 1").Scan(&pemStr)
+	if err == sql.ErrNoRows {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		encoded := string(pem.EncodeToMemory(block))
+		if _, err := db.Exec("INSERT INTO ap_keys (id, private_key_pem) VALUES (1, ?)", encoded); err != nil {
+			return nil, err
+		}
+		log.Println("activitypub: generated new actor signing key")
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM for stored actor key")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
@@ -0,0 +1,281 @@
+package activitypub
+
+import (
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ismi-abbas/reading-list/ssrf"
+)
+
+const activityStreamsContentType = `application/activity+json`
+
+// Reading is the subset of main.Reading the outbox needs to render an
+// Announce activity. Kept separate so this package has no dependency on
+// package main.
+type Reading struct {
+	Id          int64
+	Url         string
+	Title       string
+	Description string
+}
+
+// WebfingerHandler answers `/.well-known/webfinger?resource=acct:name@host`
+// with a link to that user's actor document, which is how a remote server
+// discovers a local user from a "@name@host" handle.
+func (s *Service) WebfingerHandler(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	if !strings.HasPrefix(resource, "acct:") {
+		http.Error(w, "Unsupported resource", http.StatusBadRequest)
+		return
+	}
+
+	username := strings.SplitN(strings.TrimPrefix(resource, "acct:"), "@", 2)[0]
+	userID, err := s.userIDByUsername(username)
+	if err == sql.ErrNoRows {
+		http.Error(w, "No such user", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("activitypub: looking up user %q for webfinger: %v", username, err)
+		http.Error(w, "Failed to resolve user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jrd+json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subject": resource,
+		"links": []map[string]string{
+			{
+				"rel":  "self",
+				"type": activityStreamsContentType,
+				"href": s.actorID(userID),
+			},
+		},
+	})
+}
+
+// ActorHandler serves the actor document other servers fetch to learn a
+// user's inbox/outbox URLs and public key. The signing key pair is shared
+// across actors; only the IRIs and preferredUsername differ per user.
+func (s *Service) ActorHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userID"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user", http.StatusBadRequest)
+		return
+	}
+
+	username, err := s.usernameByID(userID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "No such user", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("activitypub: looking up user %d for actor document: %v", userID, err)
+		http.Error(w, "Failed to resolve user", http.StatusInternalServerError)
+		return
+	}
+
+	publicKeyPEM := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&s.privateKey.PublicKey),
+	}))
+
+	actorID := s.actorID(userID)
+	base := s.userBase(userID)
+	w.Header().Set("Content-Type", activityStreamsContentType)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"@context":          []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"},
+		"id":                actorID,
+		"type":              "Person",
+		"preferredUsername": username,
+		"inbox":             base + "/inbox",
+		"outbox":            base + "/outbox",
+		"followers":         base + "/followers",
+		"publicKey": map[string]string{
+			"id":           actorID + "#main-key",
+			"owner":        actorID,
+			"publicKeyPem": publicKeyPEM,
+		},
+	})
+}
+
+// OutboxHandler renders one user's readings as an Announce activity in an
+// OrderedCollection, the minimal shape a follower's timeline expects.
+func (s *Service) OutboxHandler(userID int64, readings []Reading) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items := make([]map[string]interface{}, 0, len(readings))
+		for _, reading := range readings {
+			items = append(items, s.announceActivity(userID, reading))
+		}
+
+		w.Header().Set("Content-Type", activityStreamsContentType)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"@context":     "https://www.w3.org/ns/activitystreams",
+			"id":           s.userBase(userID) + "/outbox",
+			"type":         "OrderedCollection",
+			"totalItems":   len(items),
+			"orderedItems": items,
+		})
+	}
+}
+
+// FollowersHandler serves the followers collection the actor document
+// advertises: every actor_id recorded in ap_followers for userID.
+func (s *Service) FollowersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userID"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := s.db.Query("SELECT actor_id FROM ap_followers WHERE user_id = ?", userID)
+	if err != nil {
+		log.Printf("activitypub: fetching followers for user %d: %v", userID, err)
+		http.Error(w, "Failed to build followers collection", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var items []string
+	for rows.Next() {
+		var actorID string
+		if err := rows.Scan(&actorID); err != nil {
+			log.Printf("activitypub: scanning follower for user %d: %v", userID, err)
+			http.Error(w, "Failed to build followers collection", http.StatusInternalServerError)
+			return
+		}
+		items = append(items, actorID)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("activitypub: iterating followers for user %d: %v", userID, err)
+		http.Error(w, "Failed to build followers collection", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", activityStreamsContentType)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"@context":     "https://www.w3.org/ns/activitystreams",
+		"id":           s.userBase(userID) + "/followers",
+		"type":         "OrderedCollection",
+		"totalItems":   len(items),
+		"orderedItems": items,
+	})
+}
+
+func (s *Service) announceActivity(userID int64, reading Reading) map[string]interface{} {
+	objectID := fmt.Sprintf("%s/readings/%d", s.baseURL, reading.Id)
+	return map[string]interface{}{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"id":       objectID + "/activity",
+		"type":     "Announce",
+		"actor":    s.actorID(userID),
+		"object": map[string]interface{}{
+			"id":      objectID,
+			"type":    "Article",
+			"url":     reading.Url,
+			"name":    reading.Title,
+			"content": reading.Description,
+		},
+	}
+}
+
+// InboxHandler accepts incoming activities addressed to one user's inbox.
+// Only Follow is handled: we record the follower against that user and
+// reply with Accept, as required before we're allowed to deliver to them.
+func (s *Service) InboxHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userID"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user", http.StatusBadRequest)
+		return
+	}
+
+	var activity map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
+		http.Error(w, "Invalid activity", http.StatusBadRequest)
+		return
+	}
+
+	activityType, _ := activity["type"].(string)
+	if activityType != "Follow" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	actorID, _ := activity["actor"].(string)
+	if actorID == "" {
+		http.Error(w, "Missing actor", http.StatusBadRequest)
+		return
+	}
+
+	inboxURL, err := s.resolveInbox(actorID)
+	if err != nil {
+		log.Printf("activitypub: resolving follower inbox: %v", err)
+		http.Error(w, "Could not resolve follower actor", http.StatusBadGateway)
+		return
+	}
+
+	if _, err := s.db.Exec(
+		"INSERT OR IGNORE INTO ap_followers (user_id, actor_id, inbox_url) VALUES (?, ?, ?)",
+		userID, actorID, inboxURL,
+	); err != nil {
+		log.Printf("activitypub: recording follower: %v", err)
+		http.Error(w, "Failed to record follower", http.StatusInternalServerError)
+		return
+	}
+
+	localActorID := s.actorID(userID)
+	accept := map[string]interface{}{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"id":       localActorID + "/accepts/" + actorID,
+		"type":     "Accept",
+		"actor":    localActorID,
+		"object":   activity,
+	}
+	s.enqueue(userID, inboxURL, accept)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// resolveInbox fetches the follower's actor document to learn its inbox
+// URL, the same way we're discovered via our own ActorHandler.
+func (s *Service) resolveInbox(actorID string) (string, error) {
+	ip, err := ssrf.ValidateURL(actorID)
+	if err != nil {
+		return "", fmt.Errorf("actor %s: %w", actorID, err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, actorID, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", activityStreamsContentType)
+
+	res, err := ssrf.PinnedClient(ip).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var actor struct {
+		Inbox string `json:"inbox"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&actor); err != nil {
+		return "", err
+	}
+	if actor.Inbox == "" {
+		return "", fmt.Errorf("actor %s has no inbox", actorID)
+	}
+	if _, err := ssrf.ValidateURL(actor.Inbox); err != nil {
+		return "", fmt.Errorf("inbox %s: %w", actor.Inbox, err)
+	}
+	return actor.Inbox, nil
+}
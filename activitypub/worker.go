@@ -0,0 +1,116 @@
+package activitypub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ismi-abbas/reading-list/ssrf"
+)
+
+const maxDeliveryAttempts = 5
+
+type delivery struct {
+	userID   int64
+	inboxURL string
+	activity map[string]interface{}
+	attempt  int
+}
+
+// startWorker runs the background delivery loop. Failed deliveries are
+// retried with exponential backoff up to maxDeliveryAttempts before being
+// dropped, so one unreachable follower can't stall the others.
+func (s *Service) startWorker() {
+	go func() {
+		for d := range s.deliveries {
+			if err := s.deliver(d); err != nil {
+				log.Printf("activitypub: delivery to %s failed (attempt %d): %v", d.inboxURL, d.attempt, err)
+				s.retry(d)
+			}
+		}
+	}()
+}
+
+func (s *Service) retry(d delivery) {
+	d.attempt++
+	if d.attempt >= maxDeliveryAttempts {
+		log.Printf("activitypub: giving up on delivery to %s after %d attempts", d.inboxURL, d.attempt)
+		return
+	}
+
+	backoff := time.Duration(1<<uint(d.attempt)) * time.Second
+	time.AfterFunc(backoff, func() {
+		s.deliveries <- d
+	})
+}
+
+func (s *Service) deliver(d delivery) error {
+	ip, err := ssrf.ValidateURL(d.inboxURL)
+	if err != nil {
+		return fmt.Errorf("inbox %s: %w", d.inboxURL, err)
+	}
+
+	body, err := json.Marshal(d.activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.inboxURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", activityStreamsContentType)
+
+	if err := s.signRequest(req, d.userID); err != nil {
+		return err
+	}
+
+	res, err := ssrf.PinnedClient(ip).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return &deliveryError{status: res.StatusCode}
+	}
+	return nil
+}
+
+type deliveryError struct {
+	status int
+}
+
+func (e *deliveryError) Error() string {
+	return http.StatusText(e.status)
+}
+
+func (s *Service) enqueue(userID int64, inboxURL string, activity map[string]interface{}) {
+	s.deliveries <- delivery{userID: userID, inboxURL: inboxURL, activity: activity}
+}
+
+// AnnounceReading fans a newly added reading out to userID's own followers'
+// inboxes as an Announce activity. Called from AddReading after a
+// successful insert so new readings show up in that user's followers'
+// timelines only.
+func (s *Service) AnnounceReading(userID int64, reading Reading) error {
+	rows, err := s.db.Query("SELECT inbox_url FROM ap_followers WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	activity := s.announceActivity(userID, reading)
+	for rows.Next() {
+		var inboxURL string
+		if err := rows.Scan(&inboxURL); err != nil {
+			log.Printf("activitypub: scanning follower inbox: %v", err)
+			continue
+		}
+		s.enqueue(userID, inboxURL, activity)
+	}
+	return rows.Err()
+}
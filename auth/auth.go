@@ -0,0 +1,192 @@
+// Package auth turns the app multi-tenant: it issues JWTs for signed-up
+// users and provides a mux middleware that resolves those tokens (or a
+// session cookie) into a userID on the request context.
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "userID"
+
+const sessionCookieName = "session_token"
+const defaultSessionTTL = 24 * time.Hour
+
+// ErrInvalidCredentials is returned by Login when the username doesn't
+// exist or the password doesn't match.
+var ErrInvalidCredentials = errors.New("auth: invalid username or password")
+
+// ErrUsernameTaken is returned by Signup when the username already exists.
+var ErrUsernameTaken = errors.New("auth: username already taken")
+
+// Service issues and validates JWTs and owns the users table.
+type Service struct {
+	db     *sql.DB
+	secret []byte
+}
+
+// NewService reads the signing secret from the JWT_SECRET environment
+// variable and ensures the users table exists.
+func NewService(db *sql.DB) (*Service, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("auth: JWT_SECRET is not set")
+	}
+
+	s := &Service{db: db, secret: []byte(secret)}
+	if err := s.ensureSchema(); err != nil {
+		return nil, fmt.Errorf("auth: ensuring schema: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Service) ensureSchema() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// Signup creates a new user with a bcrypt-hashed password and returns its id.
+func (s *Service) Signup(username, password string) (int64, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("auth: hashing password: %w", err)
+	}
+
+	result, err := s.db.Exec("INSERT INTO users (username, password_hash) VALUES (?, ?)", username, string(hash))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return 0, ErrUsernameTaken
+		}
+		return 0, fmt.Errorf("auth: inserting user: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// Login verifies username/password and returns a signed JWT valid for the
+// browser session.
+func (s *Service) Login(username, password string) (string, error) {
+	var userID int64
+	var hash string
+	err := s.db.QueryRow("SELECT id, password_hash FROM users WHERE username = ?", username).Scan(&userID, &hash)
+	if err == sql.ErrNoRows {
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", fmt.Errorf("auth: looking up user: %w", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return "", ErrInvalidCredentials
+	}
+
+	return s.issueToken(userID, defaultSessionTTL)
+}
+
+// IssueAPIToken returns a long-lived token for scripting, suitable for
+// third-party tools to authenticate programmatic POSTs with.
+func (s *Service) IssueAPIToken(userID int64) (string, error) {
+	return s.issueToken(userID, 365*24*time.Hour)
+}
+
+func (s *Service) issueToken(userID int64, ttl time.Duration) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   fmt.Sprintf("%d", userID),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+func (s *Service) validateToken(tokenString string) (int64, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, fmt.Errorf("auth: invalid token: %w", err)
+	}
+
+	var userID int64
+	if _, err := fmt.Sscanf(claims.Subject, "%d", &userID); err != nil {
+		return 0, fmt.Errorf("auth: invalid subject claim: %w", err)
+	}
+	return userID, nil
+}
+
+// Middleware validates the Authorization: Bearer header, falling back to
+// the browser session cookie, and injects the resolved userID into the
+// request context.
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			if cookie, err := r.Cookie(sessionCookieName); err == nil {
+				token = cookie.Value
+			}
+		}
+		if token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		userID, err := s.validateToken(token)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(header, "Bearer ")
+}
+
+// UserID reads the userID a Middleware call injected into ctx.
+func UserID(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(int64)
+	return userID, ok
+}
+
+// SetSessionCookie sets the secure cookie the browser UI uses to carry a
+// session token across requests.
+func SetSessionCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(defaultSessionTTL),
+	})
+}
@@ -0,0 +1,42 @@
+package auth
+
+import "testing"
+
+func TestIssueAndValidateToken(t *testing.T) {
+	s := &Service{secret: []byte("test-secret")}
+
+	token, err := s.issueToken(42, defaultSessionTTL)
+	if err != nil {
+		t.Fatalf("issueToken: %v", err)
+	}
+
+	userID, err := s.validateToken(token)
+	if err != nil {
+		t.Fatalf("validateToken: %v", err)
+	}
+	if userID != 42 {
+		t.Errorf("got userID %d, want 42", userID)
+	}
+}
+
+func TestValidateTokenRejectsWrongSecret(t *testing.T) {
+	signed := &Service{secret: []byte("secret-a")}
+	verifier := &Service{secret: []byte("secret-b")}
+
+	token, err := signed.issueToken(1, defaultSessionTTL)
+	if err != nil {
+		t.Fatalf("issueToken: %v", err)
+	}
+
+	if _, err := verifier.validateToken(token); err == nil {
+		t.Error("expected validateToken to reject a token signed with a different secret")
+	}
+}
+
+func TestValidateTokenRejectsGarbage(t *testing.T) {
+	s := &Service{secret: []byte("test-secret")}
+
+	if _, err := s.validateToken("not-a-jwt"); err == nil {
+		t.Error("expected validateToken to reject a malformed token")
+	}
+}
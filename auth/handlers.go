@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// SignupHandler creates a new user from a JSON {username, password} body.
+func (s *Service) SignupHandler(w http.ResponseWriter, r *http.Request) {
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds.Username == "" || creds.Password == "" {
+		http.Error(w, "username and password are required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := s.Signup(creds.Username, creds.Password)
+	if err != nil {
+		if errors.Is(err, ErrUsernameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to sign up", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := s.issueToken(userID, defaultSessionTTL)
+	if err != nil {
+		http.Error(w, "Failed to sign up", http.StatusInternalServerError)
+		return
+	}
+
+	SetSessionCookie(w, token)
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+// LoginHandler exchanges a JSON {username, password} body for a session JWT.
+func (s *Service) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "username and password are required", http.StatusBadRequest)
+		return
+	}
+
+	token, err := s.Login(creds.Username, creds.Password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	SetSessionCookie(w, token)
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+// TokenHandler returns a long-lived API token for the authenticated user,
+// for scripting and third-party tools to POST readings programmatically.
+func (s *Service) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := s.IssueAPIToken(userID)
+	if err != nil {
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
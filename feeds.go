@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const feedPollInterval = 15 * time.Minute
+
+type rssFeed struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+}
+
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title   string `xml:"title"`
+	Summary string `xml:"summary"`
+	Link    struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+type feedItem struct {
+	url, title, description string
+}
+
+// StartFeedPolling launches a background goroutine that, on a fixed
+// schedule, checks every reading whose Source looks like an RSS/Atom feed
+// URL and inserts any new items it finds as unread readings - turning
+// subscriptions into a lightweight reader on top of the readings table.
+func StartFeedPolling(db *sql.DB) {
+	ticker := time.NewTicker(feedPollInterval)
+	go func() {
+		for range ticker.C {
+			pollSubscribedFeeds(db)
+		}
+	}()
+}
+
+type feedSubscription struct {
+	userID int64
+	source string
+}
+
+func pollSubscribedFeeds(db *sql.DB) {
+	rows, err := db.Query(`SELECT DISTINCT user_id, source FROM readings
+		WHERE source LIKE '%.xml' OR source LIKE '%/feed%' OR source LIKE '%/rss%'`)
+	if err != nil {
+		log.Printf("Error listing subscribed feeds: %v", err)
+		return
+	}
+
+	var subs []feedSubscription
+	for rows.Next() {
+		var sub feedSubscription
+		if err := rows.Scan(&sub.userID, &sub.source); err != nil {
+			log.Printf("Error scanning feed subscription: %v", err)
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	rows.Close()
+
+	for _, sub := range subs {
+		if err := pollFeed(db, sub.userID, sub.source); err != nil {
+			log.Printf("Error polling feed %s: %v", sub.source, err)
+		}
+	}
+}
+
+func pollFeed(db *sql.DB, userID int64, feedURL string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(feedURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range parseFeedItems(body) {
+		if item.url == "" {
+			continue
+		}
+
+		var exists int
+		if err := db.QueryRow("SELECT COUNT(*) FROM readings WHERE url = ? AND user_id = ?", item.url, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+
+		_, err := db.Exec(
+			"INSERT INTO readings (url, title, description, source, type, status, tags, summary, image_url, user_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			item.url, item.title, item.description, feedURL, string(Article), ToBeRead, "", "", "", userID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFeedItems tries RSS 2.0 first and falls back to Atom, since both
+// are plain XML and a feed URL's actual format isn't known ahead of time.
+func parseFeedItems(body []byte) []feedItem {
+	var rss rssFeed
+	if err := xml.Unmarshal(body, &rss); err == nil && len(rss.Channel.Items) > 0 {
+		items := make([]feedItem, 0, len(rss.Channel.Items))
+		for _, i := range rss.Channel.Items {
+			items = append(items, feedItem{url: strings.TrimSpace(i.Link), title: i.Title, description: i.Description})
+		}
+		return items
+	}
+
+	var atom atomFeed
+	if err := xml.Unmarshal(body, &atom); err == nil && len(atom.Entries) > 0 {
+		items := make([]feedItem, 0, len(atom.Entries))
+		for _, e := range atom.Entries {
+			items = append(items, feedItem{url: strings.TrimSpace(e.Link.Href), title: e.Title, description: e.Summary})
+		}
+		return items
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestParseFeedItemsRSS(t *testing.T) {
+	body := []byte(`<?xml version="1.0"?>
+	<rss><channel>
+		<item><title>First</title><link>https://example.com/1</link><description>desc one</description></item>
+		<item><title>Second</title><link>https://example.com/2</link><description>desc two</description></item>
+	</channel></rss>`)
+
+	items := parseFeedItems(body)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].url != "https://example.com/1" || items[0].title != "First" {
+		t.Errorf("unexpected first item: %+v", items[0])
+	}
+}
+
+func TestParseFeedItemsAtom(t *testing.T) {
+	body := []byte(`<?xml version="1.0"?>
+	<feed>
+		<entry>
+			<title>Only entry</title>
+			<summary>a summary</summary>
+			<link href="https://example.com/entry"/>
+		</entry>
+	</feed>`)
+
+	items := parseFeedItems(body)
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	if items[0].url != "https://example.com/entry" || items[0].description != "a summary" {
+		t.Errorf("unexpected entry: %+v", items[0])
+	}
+}
+
+func TestParseFeedItemsUnrecognized(t *testing.T) {
+	if items := parseFeedItems([]byte("not xml at all")); items != nil {
+		t.Errorf("got %v, want nil for unparseable body", items)
+	}
+}
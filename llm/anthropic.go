@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AnthropicGenerator talks to the Anthropic Messages API.
+type AnthropicGenerator struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewAnthropicGenerator reads ANTHROPIC_API_KEY from the environment.
+// model defaults to "claude-3-5-haiku-latest" when unset.
+func NewAnthropicGenerator(model string) (*AnthropicGenerator, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("llm/anthropic: ANTHROPIC_API_KEY is not set")
+	}
+	if model == "" {
+		model = "claude-3-5-haiku-latest"
+	}
+	return &AnthropicGenerator{apiKey: apiKey, model: model, client: &http.Client{}}, nil
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+func (g *AnthropicGenerator) Generate(ctx context.Context, content string) (Result, error) {
+	reqBody := anthropicRequest{
+		Model:     g.model,
+		MaxTokens: 256,
+		System:    structuredPrompt,
+		Messages:  []anthropicMessage{{Role: "user", Content: content}},
+		Stream:    true,
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/anthropic: marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/anthropic: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", g.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/anthropic: request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("llm/anthropic: unexpected status %d", res.StatusCode)
+	}
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			return Result{}, fmt.Errorf("llm/anthropic: decoding stream event: %w", err)
+		}
+		if event.Type == "content_block_delta" {
+			full.WriteString(event.Delta.Text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, fmt.Errorf("llm/anthropic: reading stream: %w", err)
+	}
+
+	return parseStructuredResponse(full.String())
+}
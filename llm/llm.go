@@ -0,0 +1,64 @@
+// Package llm generates titles (and optionally summaries/tags) for
+// readings from whichever model backend is configured, so main.go doesn't
+// need to know the wire format of any particular provider.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Result is what a TitleGenerator produces for a piece of content. Summary
+// and Tags are only populated when the backend was asked for structured
+// JSON output; callers should treat a zero value as "not generated".
+type Result struct {
+	Title   string
+	Summary string
+	Tags    []string
+}
+
+// TitleGenerator produces a Result for the given content. Implementations
+// must respect ctx cancellation/deadline instead of using a fixed timeout,
+// so a generation can be tied to the lifetime of the HTTP request that
+// triggered it.
+type TitleGenerator interface {
+	Generate(ctx context.Context, content string) (Result, error)
+}
+
+var systemPrompt = `You are an expert summarizer with a unique ability to distill complex information into concise, descriptive titles. Your role is to take any input text and create a single, clear title that captures its essence. The title should be informative yet brief, ideally between 3-8 words.
+Rules:
+1. Always respond with exactly one title
+2. Never include additional explanations
+3. Focus on the main theme or key message
+4. Use clear, descriptive language
+5. Avoid unnecessary articles (a, an, the)
+6. Keep character count under 60`
+
+// structuredPrompt is used instead of systemPrompt when the caller wants
+// {title, summary, tags[]} back in a single call.
+var structuredPrompt = systemPrompt + `
+Respond with nothing but a single JSON object of the shape {"title": string, "summary": string, "tags": string[]}. The summary should be 1-2 sentences. Tags should be 2-5 short lowercase keywords.`
+
+// FromEnv builds the TitleGenerator selected by the LLM_BACKEND environment
+// variable ("ollama", "openai", or "anthropic"; defaults to "ollama" to
+// match this project's original Llama 3 setup). LLM_MODEL overrides the
+// backend's default model.
+func FromEnv() (TitleGenerator, error) {
+	backend := os.Getenv("LLM_BACKEND")
+	if backend == "" {
+		backend = "ollama"
+	}
+	model := os.Getenv("LLM_MODEL")
+
+	switch backend {
+	case "ollama":
+		return NewOllamaGenerator(model), nil
+	case "openai":
+		return NewOpenAIGenerator(model)
+	case "anthropic":
+		return NewAnthropicGenerator(model)
+	default:
+		return nil, fmt.Errorf("llm: unknown LLM_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// OllamaGenerator talks to a local or LAN Ollama instance's /api/chat.
+type OllamaGenerator struct {
+	model  string
+	client *http.Client
+}
+
+// NewOllamaGenerator returns a generator for the given model, defaulting to
+// "llama3" to match this project's original behavior.
+func NewOllamaGenerator(model string) *OllamaGenerator {
+	if model == "" {
+		model = "llama3"
+	}
+	return &OllamaGenerator{model: model, client: &http.Client{}}
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   string          `json:"format,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatChunk struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+}
+
+func (g *OllamaGenerator) Generate(ctx context.Context, content string) (Result, error) {
+	baseURL := availableOllamaURL(ctx)
+	if baseURL == "" {
+		return Result{}, fmt.Errorf("llm/ollama: no available Ollama endpoint")
+	}
+
+	reqBody := ollamaChatRequest{
+		Model: g.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: structuredPrompt},
+			{Role: "user", Content: content},
+		},
+		Stream: true,
+		Format: "json",
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/ollama: marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/ollama: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/ollama: request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return Result{}, fmt.Errorf("llm/ollama: decoding stream chunk: %w", err)
+		}
+		full.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, fmt.Errorf("llm/ollama: reading stream: %w", err)
+	}
+
+	return parseStructuredResponse(full.String())
+}
+
+// availableOllamaURL checks the candidate hosts this project has
+// traditionally run Ollama on and returns the first one that responds.
+func availableOllamaURL(ctx context.Context) string {
+	candidates := []string{
+		"http://localhost:11434",
+		os.Getenv("LLAMA_API_URL_WINDOWS"),
+		os.Getenv("LLAMA_API_URL_LINUX"),
+	}
+
+	for _, baseURL := range candidates {
+		if baseURL == "" {
+			continue
+		}
+		if pingOllama(ctx, baseURL) {
+			return baseURL
+		}
+	}
+	return ""
+}
+
+func pingOllama(ctx context.Context, baseURL string) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(checkCtx, http.MethodGet, baseURL, nil)
+	if err != nil {
+		return false
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+	return res.StatusCode == http.StatusOK
+}
+
+// parseStructuredResponse decodes the {title, summary, tags[]} JSON a
+// model returns when asked for format: "json". If decoding fails, the raw
+// text is used as the title so a malformed response still degrades
+// gracefully instead of producing nothing.
+func parseStructuredResponse(raw string) (Result, error) {
+	var structured struct {
+		Title   string   `json:"title"`
+		Summary string   `json:"summary"`
+		Tags    []string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(raw), &structured); err != nil {
+		return Result{Title: strings.TrimSpace(raw)}, nil
+	}
+	return Result{Title: structured.Title, Summary: structured.Summary, Tags: structured.Tags}, nil
+}
@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OpenAIGenerator talks to any OpenAI-compatible /v1/chat/completions
+// endpoint (OpenAI itself, or a local server that mimics its API).
+type OpenAIGenerator struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAIGenerator reads OPENAI_API_KEY and optional OPENAI_BASE_URL from
+// the environment. model defaults to "gpt-4o-mini" when unset.
+func NewOpenAIGenerator(model string) (*OpenAIGenerator, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("llm/openai: OPENAI_API_KEY is not set")
+	}
+
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+
+	return &OpenAIGenerator{baseURL: baseURL, apiKey: apiKey, model: model, client: &http.Client{}}, nil
+}
+
+type openAIChatRequest struct {
+	Model          string              `json:"model"`
+	Messages       []ollamaMessage     `json:"messages"`
+	ResponseFormat *openAIResponseType `json:"response_format,omitempty"`
+	Stream         bool                `json:"stream"`
+}
+
+type openAIResponseType struct {
+	Type string `json:"type"`
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+const openAIStreamDone = "[DONE]"
+
+func (g *OpenAIGenerator) Generate(ctx context.Context, content string) (Result, error) {
+	reqBody := openAIChatRequest{
+		Model: g.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: structuredPrompt},
+			{Role: "user", Content: content},
+		},
+		ResponseFormat: &openAIResponseType{Type: "json_object"},
+		Stream:         true,
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/openai: marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/openai: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("llm/openai: request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("llm/openai: unexpected status %d", res.StatusCode)
+	}
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "data: ")
+		if line == "" || line == openAIStreamDone {
+			continue
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return Result{}, fmt.Errorf("llm/openai: decoding stream chunk: %w", err)
+		}
+		if len(chunk.Choices) > 0 {
+			full.WriteString(chunk.Choices[0].Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, fmt.Errorf("llm/openai: reading stream: %w", err)
+	}
+
+	return parseStructuredResponse(full.String())
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"html/template"
-	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,10 +16,20 @@ import (
 	"github.com/joho/godotenv"
 
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
+
+	"github.com/ismi-abbas/reading-list/activitypub"
+	"github.com/ismi-abbas/reading-list/auth"
+	"github.com/ismi-abbas/reading-list/llm"
+	"github.com/ismi-abbas/reading-list/metadata"
 )
 
 var tmpl *template.Template
 var db *sql.DB
+var ap *activitypub.Service
+var titleGenerator llm.TitleGenerator
+var authService *auth.Service
+
+const titleGenerationTimeout = 30 * time.Second
 
 type Reading struct {
 	Id          int64
@@ -29,6 +39,9 @@ type Reading struct {
 	Source      string
 	Type        ReadingType
 	Status      ReadingStatus
+	Tags        string
+	Summary     string
+	ImageUrl    string
 	AddDate     string
 	AddTime     string
 }
@@ -97,6 +110,10 @@ func initDb() {
 		source TEXT,
 		type TEXT,
 		status TEXT,
+		tags TEXT,
+		summary TEXT,
+		image_url TEXT,
+		user_id INTEGER,
 		add_date DATE DEFAULT CURRENT_DATE,
 		add_time TIME DEFAULT CURRENT_TIME
 	)`
@@ -151,15 +168,60 @@ func main() {
 	initDb()
 	defer db.Close()
 
+	if err := ensureSearchIndex(db); err != nil {
+		log.Fatal(err)
+	}
+	if err := metadata.EnsureSchema(db); err != nil {
+		log.Fatal(err)
+	}
+
+	var genErr error
+	titleGenerator, genErr = llm.FromEnv()
+	if genErr != nil {
+		log.Fatal(genErr)
+	}
+
+	var authErr error
+	authService, authErr = auth.NewService(db)
+	if authErr != nil {
+		log.Fatal(authErr)
+	}
+
 	gRouter := mux.NewRouter()
-	gRouter.HandleFunc("/", Homepage)
-	gRouter.HandleFunc("/getReadingList", FetchReadings).Methods("GET")
-	gRouter.HandleFunc("/addReading", AddReading).Methods("POST")
-	gRouter.HandleFunc("/newReadingForm", AddReadingForm)
-	gRouter.HandleFunc("/getReadingUpdateForm/{id}", EditReadingForm)
-	gRouter.HandleFunc("/readings/{id}/delete", DeleteReading).Methods("DELETE")
-
-	err := http.ListenAndServe(":8080", gRouter)
+	gRouter.HandleFunc("/signup", authService.SignupHandler).Methods("POST")
+	gRouter.HandleFunc("/login", authService.LoginHandler).Methods("POST")
+
+	protected := gRouter.NewRoute().Subrouter()
+	protected.Use(authService.Middleware)
+	protected.HandleFunc("/", Homepage)
+	protected.HandleFunc("/getReadingList", FetchReadings).Methods("GET")
+	protected.HandleFunc("/addReading", AddReading).Methods("POST")
+	protected.HandleFunc("/newReadingForm", AddReadingForm)
+	protected.HandleFunc("/getReadingUpdateForm/{id}", EditReadingForm)
+	protected.HandleFunc("/readings/{id}/delete", DeleteReading).Methods("DELETE")
+	protected.HandleFunc("/export.opml", ExportOPML).Methods("GET")
+	protected.HandleFunc("/import.opml", ImportOPML).Methods("POST")
+	protected.HandleFunc("/search", SearchHandler).Methods("GET")
+	protected.HandleFunc("/me/token", authService.TokenHandler).Methods("GET")
+
+	StartFeedPolling(db)
+
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	var err error
+	ap, err = activitypub.NewService(db, baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gRouter.HandleFunc("/.well-known/webfinger", ap.WebfingerHandler).Methods("GET")
+	gRouter.HandleFunc("/users/{userID}/actor", ap.ActorHandler).Methods("GET")
+	gRouter.HandleFunc("/users/{userID}/outbox", OutboxHandler).Methods("GET")
+	gRouter.HandleFunc("/users/{userID}/inbox", ap.InboxHandler).Methods("POST")
+	gRouter.HandleFunc("/users/{userID}/followers", ap.FollowersHandler).Methods("GET")
+
+	err = http.ListenAndServe(":8080", gRouter)
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -167,11 +229,12 @@ func main() {
 }
 
 func Homepage(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
 	types := []string{"Article", "Blog Post", "Documentation", "Book", "Tutorial"}
-	unreadCount, _ := GetCountByStatus(db, Unread)
-	readCount, _ := GetCountByStatus(db, Read)
-	toBeReadCount, _ := GetCountByStatus(db, ToBeRead)
-	halfwayCount, _ := GetCountByStatus(db, Halfway)
+	unreadCount, _ := GetCountByStatus(db, userID, Unread)
+	readCount, _ := GetCountByStatus(db, userID, Read)
+	toBeReadCount, _ := GetCountByStatus(db, userID, ToBeRead)
+	halfwayCount, _ := GetCountByStatus(db, userID, Halfway)
 	allCount := unreadCount + readCount + toBeReadCount + halfwayCount
 	tmpl.ExecuteTemplate(w, "index.html", map[string]interface{}{
 		"types":         types,
@@ -183,14 +246,15 @@ func Homepage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func GetCountByStatus(db *sql.DB, status ReadingStatus) (int, error) {
-	query := "SELECT COUNT(*) FROM readings WHERE status = ?"
+func GetCountByStatus(db *sql.DB, userID int64, status ReadingStatus) (int, error) {
+	query := "SELECT COUNT(*) FROM readings WHERE user_id = ? AND status = ?"
 	var count int
-	err := db.QueryRow(query, status).Scan(&count)
+	err := db.QueryRow(query, userID, status).Scan(&count)
 	return count, err
 }
 
 func FetchReadings(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
 	status := r.URL.Query().Get("status")
 	fmt.Printf("status: %s\n", status)
 
@@ -198,9 +262,9 @@ func FetchReadings(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if status == "all" || status == "" {
-		readings, err = GetReadings(db)
+		readings, err = GetReadings(db, userID)
 	} else {
-		readings, err = GetReadingsByStatus(db, status)
+		readings, err = GetReadingsByStatus(db, userID, status)
 	}
 
 	if err != nil {
@@ -217,9 +281,9 @@ func FetchReadings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func GetReadingsByStatus(db *sql.DB, status string) ([]Reading, error) {
-	query := "SELECT id, url, title, description, source, type, status, add_date, add_time FROM readings WHERE status = ?"
-	rows, err := db.Query(query, status)
+func GetReadingsByStatus(db *sql.DB, userID int64, status string) ([]Reading, error) {
+	query := "SELECT id, url, title, COALESCE(description, ''), source, type, status, COALESCE(tags, ''), COALESCE(summary, ''), COALESCE(image_url, ''), add_date, add_time FROM readings WHERE user_id = ? AND status = ?"
+	rows, err := db.Query(query, userID, status)
 	if err != nil {
 		return nil, err
 	}
@@ -236,6 +300,9 @@ func GetReadingsByStatus(db *sql.DB, status string) ([]Reading, error) {
 			&reading.Source,
 			&reading.Type,
 			&reading.Status,
+			&reading.Tags,
+			&reading.Summary,
+			&reading.ImageUrl,
 			&reading.AddDate,
 			&reading.AddTime,
 		)
@@ -251,9 +318,9 @@ func GetReadingsByStatus(db *sql.DB, status string) ([]Reading, error) {
 	return readings, nil
 }
 
-func GetReadings(db *sql.DB) ([]Reading, error) {
-	query := "SELECT id, url, title, description, source, type, status, add_date, add_time FROM readings"
-	rows, err := db.Query(query)
+func GetReadings(db *sql.DB, userID int64) ([]Reading, error) {
+	query := "SELECT id, url, title, COALESCE(description, ''), source, type, status, COALESCE(tags, ''), COALESCE(summary, ''), COALESCE(image_url, ''), add_date, add_time FROM readings WHERE user_id = ?"
+	rows, err := db.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -270,6 +337,9 @@ func GetReadings(db *sql.DB) ([]Reading, error) {
 			&reading.Source,
 			&reading.Type,
 			&reading.Status,
+			&reading.Tags,
+			&reading.Summary,
+			&reading.ImageUrl,
 			&reading.AddDate,
 			&reading.AddTime,
 		)
@@ -286,8 +356,9 @@ func GetReadings(db *sql.DB) ([]Reading, error) {
 }
 
 func DeleteReading(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
 	id := mux.Vars(r)["id"]
-	query := "DELETE FROM readings WHERE id = ?"
+	query := "DELETE FROM readings WHERE id = ? AND user_id = ?"
 	stmt, err := db.Prepare(query)
 	if err != nil {
 		log.Printf("Error preparing delete statement: %v", err)
@@ -296,14 +367,14 @@ func DeleteReading(w http.ResponseWriter, r *http.Request) {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
+	_, err = stmt.Exec(id, userID)
 	if err != nil {
 		log.Printf("Error executing delete: %v", err)
 		http.Error(w, "Failed to delete reading", http.StatusInternalServerError)
 		return
 	}
 
-	readings, err := GetReadings(db)
+	readings, err := GetReadings(db, userID)
 	if err != nil {
 		log.Printf("Error fetching readings after delete: %v", err)
 		http.Error(w, "Failed to fetch readings", http.StatusInternalServerError)
@@ -319,19 +390,50 @@ func DeleteReading(w http.ResponseWriter, r *http.Request) {
 }
 
 func AddReading(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
 	url := r.FormValue("url")
 	description := r.FormValue("description")
 	readingType := r.FormValue("type")
 	source := r.FormValue("source")
 
-	generatedTitle := generateTitleWithLlama3(description)
-
 	if url == "" {
 		http.Error(w, "URL and title are required", http.StatusBadRequest)
 		return
 	}
 
-	query := "INSERT INTO readings (url, title, description, type, source, status) VALUES (?, ?, ?, ?, ?, ?)"
+	ctx, cancel := context.WithTimeout(r.Context(), titleGenerationTimeout)
+	defer cancel()
+
+	title := ""
+	var tags []string
+	var summary string
+	imageURL := ""
+
+	scraped, scrapeErr := metadata.FetchWithCache(ctx, db, url)
+	if scrapeErr != nil {
+		log.Printf("Error scraping metadata for %s: %v", url, scrapeErr)
+	} else {
+		title = scraped.Title
+		imageURL = scraped.ImageURL
+		if description == "" {
+			description = scraped.Description
+		}
+		if source == "" {
+			source = scraped.Source
+		}
+	}
+
+	if title == "" {
+		generated, err := titleGenerator.Generate(ctx, description)
+		if err != nil {
+			log.Printf("Error generating title: %v", err)
+		}
+		title = generated.Title
+		tags = generated.Tags
+		summary = generated.Summary
+	}
+
+	query := "INSERT INTO readings (url, title, description, type, source, status, tags, summary, image_url, user_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := db.Prepare(query)
 	if err != nil {
 		log.Printf("Error preparing insert statement: %v", err)
@@ -340,14 +442,27 @@ func AddReading(w http.ResponseWriter, r *http.Request) {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(url, generatedTitle, description, readingType, source, Unread)
+	result, err := stmt.Exec(url, title, description, readingType, source, Unread, strings.Join(tags, ","), summary, imageURL, userID)
 	if err != nil {
 		log.Printf("Error executing insert: %v", err)
 		http.Error(w, "Failed to add reading", http.StatusInternalServerError)
 		return
 	}
 
-	readings, err := GetReadings(db)
+	if insertedID, err := result.LastInsertId(); err == nil {
+		go func() {
+			if err := ap.AnnounceReading(userID, activitypub.Reading{
+				Id:          insertedID,
+				Url:         url,
+				Title:       title,
+				Description: description,
+			}); err != nil {
+				log.Printf("Error announcing reading to followers: %v", err)
+			}
+		}()
+	}
+
+	readings, err := GetReadings(db, userID)
 	if err != nil {
 		log.Printf("Error fetching readings after insert: %v", err)
 		http.Error(w, "Failed to fetch readings", http.StatusInternalServerError)
@@ -363,141 +478,47 @@ func AddReading(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func AddReadingForm(w http.ResponseWriter, r *http.Request) {
-	tmpl.ExecuteTemplate(w, "addReadingForm", nil)
-}
-
-func EditReadingForm(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	tmpl.ExecuteTemplate(w, "editReadingForm", id)
-}
-
-var systemPrompt = `You are an expert summarizer with a unique ability to distill complex information into concise, descriptive titles. Your role is to take any input text and create a single, clear title that captures its essence. The title should be informative yet brief, ideally between 3-8 words. \n Rules: 1. Always respond with exactly one title\n 2. Never include additional explanations\n 3. Focus on the main theme or key message\n 4. Use clear, descriptive language\n 5. Avoid unnecessary articles (a, an, the)\n 6. Keep character count under 60`
-
-func checkURL(url string) bool {
-	client := &http.Client{
-		Timeout: 2 * time.Second,
-	}
-
-	resp, err := client.Get(url)
+// OutboxHandler renders the requested user's own readings as Announce
+// activities on their per-user outbox.
+func OutboxHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userID"], 10, 64)
 	if err != nil {
-		return false
+		http.Error(w, "Invalid user", http.StatusBadRequest)
+		return
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == http.StatusOK
-}
 
-func getAvailableURL() string {
-	urls := []string{
-		"http://localhost:11434",
-		os.Getenv("LLAMA_API_URL_WINDOWS"),
-		os.Getenv("LLAMA_API_URL_LINUX"),
+	rows, err := db.Query("SELECT id, url, title, COALESCE(description, '') FROM readings WHERE user_id = ?", userID)
+	if err != nil {
+		log.Printf("Error fetching readings for outbox: %v", err)
+		http.Error(w, "Failed to build outbox", http.StatusInternalServerError)
+		return
 	}
+	defer rows.Close()
 
-	for _, baseURL := range urls {
-		if baseURL == "" {
-			continue
-		}
-
-		url := baseURL + "/api/chat"
-		if checkURL(url) {
-			return baseURL
+	var readings []activitypub.Reading
+	for rows.Next() {
+		var reading activitypub.Reading
+		if err := rows.Scan(&reading.Id, &reading.Url, &reading.Title, &reading.Description); err != nil {
+			log.Printf("Error scanning reading for outbox: %v", err)
+			http.Error(w, "Failed to build outbox", http.StatusInternalServerError)
+			return
 		}
+		readings = append(readings, reading)
 	}
-
-	return ""
-}
-
-func generateTitleWithLlama3(content string) string {
-	baseURL := getAvailableURL()
-	if baseURL == "" {
-		fmt.Println("No available Llama API endpoints")
-		return ""
-	}
-
-	url := baseURL + "/api/chat"
-	method := "POST"
-
-	fmt.Println("Using URL:", url)
-	fmt.Println("content:", content)
-
-	// Escape special characters in the content
-	escapedContent := strings.ReplaceAll(content, "\\", "\\\\")
-	escapedContent = strings.ReplaceAll(escapedContent, "\"", "\\\"")
-	escapedContent = strings.ReplaceAll(escapedContent, "\n", "\\n")
-	escapedContent = strings.ReplaceAll(escapedContent, "\r", "\\r")
-	escapedContent = strings.ReplaceAll(escapedContent, "\t", "\\t")
-
-	// create json payload
-	payload := strings.NewReader(`{
-		"model": "llama3",
-		"messages": [
-			{
-				"role": "system",
-				"content": "` + systemPrompt + `"
-			},
-			{
-				"role": "user",
-				"content": "` + escapedContent + `"
-			}
-		],
-		"stream": false
-	}`)
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	req, err := http.NewRequest(method, url, payload)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return ""
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating readings for outbox: %v", err)
+		http.Error(w, "Failed to build outbox", http.StatusInternalServerError)
+		return
 	}
-	req.Header.Add("Content-Type", "application/json")
-
-	// Try up to 3 times
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		res, err := client.Do(req)
-		if err != nil {
-			fmt.Printf("Attempt %d failed: %v\n", i+1, err)
-			if i < maxRetries-1 {
-				time.Sleep(time.Second * 2) // Wait 2 seconds before retrying
-				continue
-			}
-			return ""
-		}
-		defer res.Body.Close()
 
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			fmt.Println("Error reading response:", err)
-			return ""
-		}
-
-		// Parse the JSON response
-		var response struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		}
-
-		if err := json.Unmarshal(body, &response); err != nil {
-			fmt.Println("Error parsing response:", err)
-			return ""
-		}
-
-		if response.Message.Content != "" {
-			return response.Message.Content
-		}
+	ap.OutboxHandler(userID, readings)(w, r)
+}
 
-		// If we got an empty response and have more retries, try again
-		if i < maxRetries-1 {
-			time.Sleep(time.Second * 2)
-			continue
-		}
-	}
+func AddReadingForm(w http.ResponseWriter, r *http.Request) {
+	tmpl.ExecuteTemplate(w, "addReadingForm", nil)
+}
 
-	return ""
+func EditReadingForm(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	tmpl.ExecuteTemplate(w, "editReadingForm", id)
 }
@@ -0,0 +1,47 @@
+package metadata
+
+import "database/sql"
+
+// EnsureSchema creates the url_metadata cache table if it doesn't exist.
+func EnsureSchema(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS url_metadata (
+		url_hash TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		title TEXT,
+		description TEXT,
+		source TEXT,
+		type TEXT,
+		image_url TEXT,
+		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+func lookup(db *sql.DB, key string) (Metadata, bool, error) {
+	var meta Metadata
+	err := db.QueryRow(
+		"SELECT title, description, source, type, image_url FROM url_metadata WHERE url_hash = ?",
+		key,
+	).Scan(&meta.Title, &meta.Description, &meta.Source, &meta.Type, &meta.ImageURL)
+
+	if err == sql.ErrNoRows {
+		return Metadata{}, false, nil
+	}
+	if err != nil {
+		return Metadata{}, false, err
+	}
+	return meta, true, nil
+}
+
+func store(db *sql.DB, key, rawURL string, meta Metadata) error {
+	_, err := db.Exec(
+		`INSERT INTO url_metadata (url_hash, url, title, description, source, type, image_url)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url_hash) DO UPDATE SET
+			title = excluded.title, description = excluded.description,
+			source = excluded.source, type = excluded.type, image_url = excluded.image_url`,
+		key, rawURL, meta.Title, meta.Description, meta.Source, meta.Type, meta.ImageURL,
+	)
+	return err
+}
@@ -0,0 +1,162 @@
+// Package metadata scrapes OpenGraph/HTML metadata from a submitted URL so
+// AddReading can pre-fill title, description, source and image fields the
+// user left blank.
+package metadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/ismi-abbas/reading-list/ssrf"
+)
+
+const (
+	fetchTimeout = 5 * time.Second
+	maxBodyBytes = 2 << 20 // 2MB
+)
+
+// Metadata is what a page yields after scraping, with fallbacks already
+// applied (e.g. Source falls back to the URL's host).
+type Metadata struct {
+	Title       string
+	Description string
+	Source      string
+	Type        string
+	ImageURL    string
+}
+
+// FetchWithCache returns cached metadata for rawURL if present, otherwise
+// scrapes it and stores the result keyed by a hash of the URL so repeated
+// adds and re-imports don't re-fetch the same page.
+func FetchWithCache(ctx context.Context, db *sql.DB, rawURL string) (Metadata, error) {
+	key := urlHash(rawURL)
+
+	if cached, ok, err := lookup(db, key); err != nil {
+		return Metadata{}, err
+	} else if ok {
+		return cached, nil
+	}
+
+	meta, err := Fetch(ctx, rawURL)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	if err := store(db, key, rawURL, meta); err != nil {
+		return Metadata{}, err
+	}
+	return meta, nil
+}
+
+// Fetch downloads rawURL with a bounded-time, size-capped client and
+// extracts OpenGraph tags, falling back to <title>, meta description, and
+// the URL's host when a tag is missing. rawURL is whatever the user typed
+// into AddReading, so it's validated the same way activitypub validates
+// actor/inbox URLs before this server dials out to it.
+func Fetch(ctx context.Context, rawURL string) (Metadata, error) {
+	ip, err := ssrf.ValidateURL(rawURL)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("metadata: %s: %w", rawURL, err)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(fetchCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("metadata: creating request: %w", err)
+	}
+
+	res, err := ssrf.PinnedClient(ip).Do(req)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("metadata: fetching %s: %w", rawURL, err)
+	}
+	defer res.Body.Close()
+
+	doc, err := html.Parse(io.LimitReader(res.Body, maxBodyBytes))
+	if err != nil {
+		return Metadata{}, fmt.Errorf("metadata: parsing html: %w", err)
+	}
+
+	meta := extractMetadata(doc)
+	if meta.Source == "" {
+		meta.Source = hostOf(rawURL)
+	}
+	return meta, nil
+}
+
+func extractMetadata(doc *html.Node) Metadata {
+	var meta Metadata
+	var fallbackTitle, fallbackDescription string
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				if n.FirstChild != nil {
+					fallbackTitle = strings.TrimSpace(n.FirstChild.Data)
+				}
+			case "meta":
+				attrs := attrMap(n)
+				switch {
+				case attrs["property"] == "og:title":
+					meta.Title = attrs["content"]
+				case attrs["property"] == "og:description":
+					meta.Description = attrs["content"]
+				case attrs["property"] == "og:site_name":
+					meta.Source = attrs["content"]
+				case attrs["property"] == "og:type":
+					meta.Type = attrs["content"]
+				case attrs["property"] == "og:image":
+					meta.ImageURL = attrs["content"]
+				case attrs["name"] == "description" && fallbackDescription == "":
+					fallbackDescription = attrs["content"]
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if meta.Title == "" {
+		meta.Title = fallbackTitle
+	}
+	if meta.Description == "" {
+		meta.Description = fallbackDescription
+	}
+	return meta
+}
+
+func attrMap(n *html.Node) map[string]string {
+	attrs := make(map[string]string, len(n.Attr))
+	for _, a := range n.Attr {
+		attrs[a.Key] = a.Val
+	}
+	return attrs
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+func urlHash(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:])
+}
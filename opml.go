@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+
+	opml "github.com/kaorimatz/go-opml"
+
+	"github.com/ismi-abbas/reading-list/auth"
+)
+
+// opmlDocument mirrors the subset of the OPML 2.0 spec we emit: one outline
+// group per ReadingType, each containing one outline per reading.
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title string `xml:"title"`
+}
+
+type opmlBody struct {
+	Outlines []opmlGroup `xml:"outline"`
+}
+
+type opmlGroup struct {
+	Text     string        `xml:"text,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	Type    string `xml:"type,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr"`
+}
+
+// ExportOPML streams every reading as an OPML 2.0 document, grouped into one
+// outline per Type so a feed reader importing it gets one folder per type.
+func ExportOPML(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
+	readings, err := GetReadings(db, userID)
+	if err != nil {
+		log.Printf("Error fetching readings for export: %v", err)
+		http.Error(w, "Failed to export readings", http.StatusInternalServerError)
+		return
+	}
+
+	groups := map[ReadingType]*opmlGroup{}
+	var order []ReadingType
+	for _, reading := range readings {
+		group, ok := groups[reading.Type]
+		if !ok {
+			group = &opmlGroup{Text: string(reading.Type)}
+			groups[reading.Type] = group
+			order = append(order, reading.Type)
+		}
+		group.Outlines = append(group.Outlines, opmlOutline{
+			Text:    reading.Title,
+			Title:   reading.Title,
+			Type:    "link",
+			XMLURL:  reading.Url,
+			HTMLURL: reading.Url,
+		})
+	}
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Head:    opmlHead{Title: "Reading List Export"},
+	}
+	for _, t := range order {
+		doc.Body.Outlines = append(doc.Body.Outlines, *groups[t])
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml+xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="reading-list.opml"`)
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		log.Printf("Error encoding OPML: %v", err)
+	}
+}
+
+// ImportOPML parses an uploaded OPML file, skips any outline whose url is
+// already present in readings, and inserts the rest in a single transaction
+// with status "to-be-read".
+func ImportOPML(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Missing OPML file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	doc, err := opml.Parse(file)
+	if err != nil {
+		log.Printf("Error parsing OPML: %v", err)
+		http.Error(w, "Failed to parse OPML file", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Error starting import transaction: %v", err)
+		http.Error(w, "Failed to import readings", http.StatusInternalServerError)
+		return
+	}
+
+	inserted := 0
+	for _, outline := range flattenOutlines(doc.Outlines) {
+		url := ""
+		if outline.XMLURL != nil {
+			url = outline.XMLURL.String()
+		} else if outline.HTMLURL != nil {
+			url = outline.HTMLURL.String()
+		}
+		if url == "" {
+			continue
+		}
+
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM readings WHERE url = ? AND user_id = ?", url, userID).Scan(&exists); err != nil {
+			tx.Rollback()
+			log.Printf("Error checking for duplicate reading: %v", err)
+			http.Error(w, "Failed to import readings", http.StatusInternalServerError)
+			return
+		}
+		if exists > 0 {
+			continue
+		}
+
+		title := outline.Title
+		if title == "" {
+			title = outline.Text
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO readings (url, title, description, source, type, status, tags, summary, image_url, user_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			url, title, "", outline.Text, string(Article), ToBeRead, "", "", "", userID,
+		)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Error inserting imported reading: %v", err)
+			http.Error(w, "Failed to import readings", http.StatusInternalServerError)
+			return
+		}
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing import transaction: %v", err)
+		http.Error(w, "Failed to import readings", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Imported %d readings from OPML", inserted)
+
+	readings, err := GetReadings(db, userID)
+	if err != nil {
+		log.Printf("Error fetching readings after import: %v", err)
+		http.Error(w, "Failed to fetch readings", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "readingList", readings); err != nil {
+		log.Printf("Error executing template: %v", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// flattenOutlines walks nested OPML outline groups and returns only the
+// leaf outlines, since categories can be nested arbitrarily deep.
+func flattenOutlines(outlines []*opml.Outline) []*opml.Outline {
+	var flat []*opml.Outline
+	for _, o := range outlines {
+		if len(o.Outlines) > 0 {
+			flat = append(flat, flattenOutlines(o.Outlines)...)
+			continue
+		}
+		flat = append(flat, o)
+	}
+	return flat
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	opml "github.com/kaorimatz/go-opml"
+)
+
+func TestFlattenOutlinesReturnsOnlyLeaves(t *testing.T) {
+	leafA := &opml.Outline{Text: "a"}
+	leafB := &opml.Outline{Text: "b"}
+	leafC := &opml.Outline{Text: "c"}
+	group := &opml.Outline{
+		Text:     "group",
+		Outlines: []*opml.Outline{leafB, leafC},
+	}
+
+	flat := flattenOutlines([]*opml.Outline{leafA, group})
+
+	if len(flat) != 3 {
+		t.Fatalf("got %d outlines, want 3", len(flat))
+	}
+	if flat[0] != leafA || flat[1] != leafB || flat[2] != leafC {
+		t.Errorf("flattenOutlines did not return the expected leaves in order: %+v", flat)
+	}
+}
+
+func TestFlattenOutlinesEmpty(t *testing.T) {
+	if flat := flattenOutlines(nil); len(flat) != 0 {
+		t.Errorf("got %d outlines, want 0", len(flat))
+	}
+}
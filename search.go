@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/ismi-abbas/reading-list/auth"
+)
+
+// ensureSearchIndex creates the readings_fts contentless FTS5 index, keeps
+// it in sync via triggers, and backfills it from any rows that existed
+// before the index did.
+func ensureSearchIndex(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS readings_fts USING fts5(
+		title, description, source,
+		content='readings', content_rowid='id'
+	)`)
+	if err != nil {
+		return err
+	}
+
+	triggers := []string{
+		`CREATE TRIGGER IF NOT EXISTS readings_ai AFTER INSERT ON readings BEGIN
+			INSERT INTO readings_fts(rowid, title, description, source) VALUES (new.id, new.title, new.description, new.source);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS readings_ad AFTER DELETE ON readings BEGIN
+			INSERT INTO readings_fts(readings_fts, rowid, title, description, source) VALUES ('delete', old.id, old.title, old.description, old.source);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS readings_au AFTER UPDATE ON readings BEGIN
+			INSERT INTO readings_fts(readings_fts, rowid, title, description, source) VALUES ('delete', old.id, old.title, old.description, old.source);
+			INSERT INTO readings_fts(rowid, title, description, source) VALUES (new.id, new.title, new.description, new.source);
+		END`,
+	}
+	for _, trigger := range triggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return err
+		}
+	}
+
+	var indexed int
+	if err := db.QueryRow("SELECT COUNT(*) FROM readings_fts").Scan(&indexed); err != nil {
+		return err
+	}
+	if indexed == 0 {
+		if _, err := db.Exec(`
+		INSERT INTO readings_fts(rowid, title, description, source)
+		SELECT id, title, description, source FROM readings`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchResult is a Reading plus a highlighted snippet of whichever field
+// matched the query, for display in the search results template.
+type SearchResult struct {
+	Reading
+	Snippet string
+}
+
+// SearchReadings runs a MATCH query against readings_fts, ranked by BM25,
+// scoped to userID's own readings, and joins back to readings for the full
+// row. q may use FTS5 prefix ("term*") and phrase ("\"exact phrase\"") syntax.
+func SearchReadings(db *sql.DB, userID int64, q string) ([]SearchResult, error) {
+	query := `
+	SELECT readings.id, readings.url, readings.title, COALESCE(readings.description, ''), readings.source,
+		readings.type, readings.status, COALESCE(readings.tags, ''), COALESCE(readings.summary, ''), COALESCE(readings.image_url, ''),
+		readings.add_date, readings.add_time,
+		snippet(readings_fts, -1, '<mark>', '</mark>', '...', 10)
+	FROM readings_fts
+	JOIN readings ON readings.id = readings_fts.rowid
+	WHERE readings_fts MATCH ? AND readings.user_id = ?
+	ORDER BY bm25(readings_fts)`
+
+	rows, err := db.Query(query, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		err := rows.Scan(
+			&result.Id,
+			&result.Url,
+			&result.Title,
+			&result.Description,
+			&result.Source,
+			&result.Type,
+			&result.Status,
+			&result.Tags,
+			&result.Summary,
+			&result.ImageUrl,
+			&result.AddDate,
+			&result.AddTime,
+			&result.Snippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SearchHandler renders an HTMX-friendly readingList fragment filtered by a
+// full-text query across title, description and source.
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	userID, _ := auth.UserID(r.Context())
+
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		if err := tmpl.ExecuteTemplate(w, "readingList", []SearchResult{}); err != nil {
+			log.Printf("Error executing template: %v", err)
+			http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	results, err := SearchReadings(db, userID, q)
+	if err != nil {
+		log.Printf("Error searching readings: %v", err)
+		http.Error(w, "Failed to search readings", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "readingList", results); err != nil {
+		log.Printf("Error executing template: %v", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,29 @@
+package ssrf
+
+import "testing"
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"public https", "https://93.184.216.34/actor", false},
+		{"public http", "http://93.184.216.34/actor", false},
+		{"loopback ip", "http://127.0.0.1/actor", true},
+		{"loopback ipv6", "http://[::1]/actor", true},
+		{"private range", "http://10.0.0.5/actor", true},
+		{"link-local", "http://169.254.169.254/actor", true},
+		{"ftp scheme", "ftp://example.com/actor", true},
+		{"no scheme", "example.com/actor", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}